@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// isBatchInput reports whether arg should be treated as a directory or glob
+// pattern rather than a single CSV file.
+func isBatchInput(arg string) bool {
+	if info, err := os.Stat(arg); err == nil {
+		return info.IsDir()
+	}
+	return strings.ContainsAny(arg, "*?[")
+}
+
+// fileResult is one file's outcome from runBatch.
+type fileResult struct {
+	path      string
+	tableName string
+	ddl       string
+	err       error
+}
+
+// runBatch discovers every *.csv file under root (a directory or glob
+// pattern), processes them concurrently with opts.Parallel workers, writes
+// one .sql file per input, and writes an aggregate schema.sql into root
+// (for a glob pattern, into the pattern's directory) containing every
+// CREATE TABLE statement. A malformed file doesn't
+// stop the batch: its error is collected into the summary printed at the
+// end, and the returned bool reports whether any file failed.
+func runBatch(root string, opts Options) (bool, error) {
+	files, err := discoverCSVFiles(root)
+	if err != nil {
+		return false, err
+	}
+	if len(files) == 0 {
+		return false, fmt.Errorf("no .csv files found for %q", root)
+	}
+
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	jobs := make(chan string)
+	results := make(chan fileResult)
+
+	var workers sync.WaitGroup
+	workers.Add(parallel)
+	for i := 0; i < parallel; i++ {
+		go func() {
+			defer workers.Done()
+			for path := range jobs {
+				results <- processFile(path, opts)
+			}
+		}()
+	}
+
+	go func() {
+		for _, f := range files {
+			jobs <- f
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	var succeeded, failed []fileResult
+	for r := range results {
+		if r.err != nil {
+			failed = append(failed, r)
+		} else {
+			succeeded = append(succeeded, r)
+		}
+	}
+	sort.Slice(succeeded, func(i, j int) bool { return succeeded[i].path < succeeded[j].path })
+	sort.Slice(failed, func(i, j int) bool { return failed[i].path < failed[j].path })
+
+	schemaPath, err := writeAggregateSchema(root, succeeded)
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Printf("Processed %d file(s): %d succeeded, %d failed\n", len(files), len(succeeded), len(failed))
+	for _, r := range failed {
+		fmt.Printf("  FAIL %s: %v\n", r.path, r.err)
+	}
+	fmt.Printf("Wrote aggregate schema to %s\n", schemaPath)
+
+	return len(failed) > 0, nil
+}
+
+// discoverCSVFiles lists the *.csv files under a directory (walked
+// recursively) or matching a glob pattern, in a stable sorted order.
+func discoverCSVFiles(root string) ([]string, error) {
+	info, statErr := os.Stat(root)
+	if statErr == nil && info.IsDir() {
+		var files []string
+		err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !fi.IsDir() && strings.EqualFold(filepath.Ext(p), ".csv") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+
+	matches, err := filepath.Glob(root)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, m := range matches {
+		if fi, err := os.Stat(m); err == nil && !fi.IsDir() && strings.EqualFold(filepath.Ext(m), ".csv") {
+			files = append(files, m)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// processFile runs the single-file pipeline for path and reports the result
+// without ever calling log.Fatal, so one bad file can't kill the batch.
+func processFile(path string, opts Options) fileResult {
+	dialect, err := dialectFor(opts.DialectName)
+	if err != nil {
+		return fileResult{path: path, err: err}
+	}
+
+	p := path
+	tableName, columns, outPath, err := ReadCSV2(&p, opts)
+	if err != nil {
+		return fileResult{path: path, err: err}
+	}
+	ddl := dialect.CreateTable(tableName, columns)
+
+	content, extraFiles, err := generateOutput(path, opts, dialect, tableName, columns, ddl, outPath)
+	if err != nil {
+		return fileResult{path: path, err: fmt.Errorf("%s: %w", path, err)}
+	}
+
+	if err := writeSQLFile(outPath, content); err != nil {
+		return fileResult{path: path, err: fmt.Errorf("%s: %w", path, err)}
+	}
+	for extraPath, extraContent := range extraFiles {
+		if err := writeSQLFile(extraPath, extraContent); err != nil {
+			return fileResult{path: path, err: fmt.Errorf("%s: %w", path, err)}
+		}
+	}
+
+	return fileResult{path: path, tableName: tableName, ddl: ddl}
+}
+
+// writeAggregateSchema concatenates every successful file's CREATE TABLE
+// statement into a schema.sql in root, or in the directory of root when
+// root is a glob pattern. Tables derived from
+// independent CSVs have no foreign keys between them, so file order is
+// already dependency-free; we just keep it stable and alphabetical.
+func writeAggregateSchema(root string, succeeded []fileResult) (string, error) {
+	dir := root
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		dir = filepath.Dir(root)
+	}
+	schemaPath := filepath.Join(dir, "schema.sql")
+
+	var buf bytes.Buffer
+	for _, r := range succeeded {
+		buf.WriteString(r.ddl)
+		buf.WriteString("\n")
+	}
+	if err := writeSQLFile(schemaPath, buf.String()); err != nil {
+		return "", err
+	}
+	return schemaPath, nil
+}
+
+// writeSQLFile writes content to path, returning an error instead of
+// panicking so callers can recover from a single file's failure.
+func writeSQLFile(path, content string) error {
+	return ioutil.WriteFile(path, []byte(content), os.FileMode(0644))
+}
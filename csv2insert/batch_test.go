@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsBatchInput(t *testing.T) {
+	dir := t.TempDir()
+	if !isBatchInput(dir) {
+		t.Error("expected a directory to be treated as batch input")
+	}
+	if isBatchInput(filepath.Join(dir, "single.csv")) {
+		t.Error("expected a plain file path to not be treated as batch input")
+	}
+	if !isBatchInput(filepath.Join(dir, "*.csv")) {
+		t.Error("expected a glob pattern to be treated as batch input")
+	}
+}
+
+// TestRunBatchPartialFailure pins down request 4's per-file error recovery:
+// one ragged file must not stop the batch or the other file's output, and
+// runBatch must report the failure via its bool return so main can set a
+// non-zero exit code.
+func TestRunBatchPartialFailure(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "good.csv"), []byte("id,name\n1,a\n2,b\n"), 0644); err != nil {
+		t.Fatalf("write good.csv: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "bad.csv"), []byte("id,name\n1,a\n2,b,extra\n"), 0644); err != nil {
+		t.Fatalf("write bad.csv: %v", err)
+	}
+
+	opts := Options{
+		NullTokens:  `"",NULL,N/A,\N`,
+		Delim:       ',',
+		Quote:       '"',
+		Encoding:    "utf-8",
+		Format:      "tql",
+		Batch:       1000,
+		Parallel:    2,
+		DialectName: "thoughtspot",
+	}
+
+	failed, err := runBatch(dir, opts)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if !failed {
+		t.Fatal("expected runBatch to report a failure for the ragged file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "goodout.sql")); err != nil {
+		t.Fatalf("expected the good file to still be processed despite the bad one: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "schema.sql")); err != nil {
+		t.Fatalf("expected aggregate schema.sql to be written: %v", err)
+	}
+}
@@ -3,117 +3,278 @@ package main
 // from https://github.com/Ahmad-Magdy/CSV-To-JSON-Converter
 
 import (
-	"bytes"
 	"encoding/csv"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/araddon/dateparse"
 )
 
+// InferredType is a column type in the bool -> int -> double -> date -> varchar
+// promotion lattice. A column's final type is the narrowest type that every
+// non-null value observed in that column fits in.
+type InferredType int
+
+// Types are ordered from narrowest to widest; promote() relies on this order.
+const (
+	TypeBool InferredType = iota
+	TypeInt
+	TypeDouble
+	TypeDate
+	TypeVarchar
+)
+
+// Column tracks the inferred type, nullability, and (for varchar) the max
+// observed byte length of a single CSV column.
+type Column struct {
+	Name     string
+	Type     InferredType
+	HasValue bool
+	Nullable bool
+	MaxLen   int
+	HasTime  bool // true if any TypeDate value carried a non-midnight time component
+}
+
+// Options configures how ReadCSV2 parses its input and infers types.
+type Options struct {
+	SampleRows  int
+	NullTokens  string
+	Delim       rune
+	Quote       rune
+	Encoding    string
+	SkipLines   int
+	NoHeader    bool
+	Format      string
+	Batch       int
+	Parallel    int
+	DialectName string
+}
+
 func main() {
-	// argsWithProg := os.Args
-	argsWithoutProg := os.Args[1:]
+	path := flag.String("path", "", "Path of the file, directory, or glob pattern (defaults to the first positional argument)")
+	sample := flag.Int("sample", 0, "Number of data rows to examine when inferring types (0 = all rows)")
+	nullTokens := flag.String("null-tokens", `"",NULL,N/A,\N`, `Comma-separated list of values treated as null (use "" for an empty field)`)
+	delim := flag.String("delim", ",", "Field delimiter: a literal character, or 'tab'/'semicolon'/'pipe'")
+	quote := flag.String("quote", `"`, "Quote character")
+	enc := flag.String("encoding", "utf-8", "Input encoding: utf-8, gbk, latin1, utf16le")
+	skipLines := flag.Int("skip-lines", 0, "Number of preamble lines to skip before the header/data")
+	noHeader := flag.Bool("no-header", false, "Treat the first row as data and synthesize column names col_1..col_N")
+	format := flag.String("format", "tql", "Output format: tql (DDL only), insert (DDL + INSERTs), tsload (DDL + tsload script), copy (DDL + COPY FROM STDIN block)")
+	batch := flag.Int("batch", 1000, "Rows per INSERT statement in -format insert")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "Number of files to process concurrently when the argument is a directory or glob pattern")
+	dialectName := flag.String("dialect", "thoughtspot", "SQL dialect: thoughtspot, postgres, mysql, snowflake, bigquery")
+	flag.Parse()
 
-	if len(argsWithoutProg) == 0 {
-		fmt.Println("Usage: csv2ins datafile.csv")
+	target := *path
+	if target == "" {
+		target = flag.Arg(0)
+	}
+	if target == "" {
+		fmt.Println("Usage: csv2ins [flags] datafile.csv")
 		os.Exit(1)
 	}
-	path := flag.String("path", argsWithoutProg[0], "Path of the file")
-	// path := argsWithoutProg[0]
-	flag.Parse()
-	fileBytes, fileNPath := ReadCSV2(path)
-	SaveFile(fileBytes, fileNPath)
-	fmt.Println(strings.Repeat("=", 10), "Done", strings.Repeat("=", 10))
-}
 
-// ReadCSV to read the content of CSV File
-func ReadCSV2(path *string) (string, string) {
-	csvFile, err := os.Open(*path)
+	delimRune, err := parseDelim(*delim)
+	if err != nil {
+		log.Fatal(err)
+	}
+	quoteRune, err := parseQuote(*quote)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dialect, err := dialectFor(*dialectName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := Options{
+		SampleRows:  *sample,
+		NullTokens:  *nullTokens,
+		Delim:       delimRune,
+		Quote:       quoteRune,
+		Encoding:    *enc,
+		SkipLines:   *skipLines,
+		NoHeader:    *noHeader,
+		Format:      *format,
+		Batch:       *batch,
+		Parallel:    *parallel,
+		DialectName: *dialectName,
+	}
+
+	if isBatchInput(target) {
+		failed, err := runBatch(target, opts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(strings.Repeat("=", 10), "Done", strings.Repeat("=", 10))
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
 
+	tableName, columns, outPath, err := ReadCSV2(&target, opts)
 	if err != nil {
-		log.Fatal("The file is not found || wrong root")
+		log.Fatal(err)
 	}
-	defer csvFile.Close()
+	ddl := dialect.CreateTable(tableName, columns)
 
-	reader := csv.NewReader(csvFile)
-	content, _ := reader.ReadAll()
+	content, extraFiles, err := generateOutput(target, opts, dialect, tableName, columns, ddl, outPath)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if len(content) < 1 {
-		log.Fatal("Something wrong, the file maybe empty or length of the lines are not the same")
+	SaveFile(content, outPath)
+	for extraPath, extraContent := range extraFiles {
+		if err := ioutil.WriteFile(extraPath, []byte(extraContent), os.FileMode(0644)); err != nil {
+			log.Fatal(err)
+		}
 	}
+	fmt.Println(strings.Repeat("=", 10), "Done", strings.Repeat("=", 10))
+}
 
-	headersArr := make([]string, 0)
-	for _, headE := range content[0] {
-		headersArr = append(headersArr, headE)
+// ReadCSV2 streams the CSV at *path row by row (bounded memory, no ReadAll)
+// and infers a SQL type for every column across the whole file (or the
+// first opts.SampleRows data rows, if set). It returns the table name, the
+// inferred columns, and the output file path, or an error if the file
+// couldn't be read.
+func ReadCSV2(path *string, opts Options) (string, []*Column, string, error) {
+	header, next, closer, err := openDataRows(*path, opts)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("%s: something wrong, the file maybe empty or length of the lines are not the same", *path)
 	}
+	defer closer.Close()
 
-	//Remove the header row
-	content = content[1:]
+	nullTokens := parseNullTokens(opts.NullTokens)
 
-	var buffer bytes.Buffer
-	// var ct string
-	fileNm := filepath.Base(*path)
-	fmt.Println("Filename from path : %s from %s", *path, fileNm)
-	justFileNm := strings.Replace(fileNm, ".csv", "", -1)
-	buffer.WriteString("CREATE TABLE ")
-	buffer.WriteString(justFileNm)
-
-	var firstField bool
-	firstField = true
-	for _, d := range content {
-		buffer.WriteString("(")
-		for j, y := range d {
-			if firstField {
-				buffer.WriteString(`"` + headersArr[j] + `" `)
-				firstField = false
-			} else {
-				buffer.WriteString(`, "` + headersArr[j] + `" `)
+	columns := make([]*Column, len(header))
+	for i, h := range header {
+		columns[i] = &Column{Name: h}
+	}
+
+	rowsRead := 0
+	for {
+		if opts.SampleRows > 0 && rowsRead >= opts.SampleRows {
+			break
+		}
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, "", fmt.Errorf("%s: error reading row: %w", *path, err)
+		}
+		rowsRead++
+
+		for j, value := range record {
+			if j >= len(columns) {
+				continue
+			}
+			col := columns[j]
+			if nullTokens[value] {
+				col.Nullable = true
+				continue
 			}
-			fmt.Println("Reading line : ", y)
-
-			_, fErr := strconv.ParseFloat(y, 64)
-			_, bErr := strconv.ParseBool(y)
-			_, iErr := strconv.ParseInt(y, 0, 32)
-			_, dtUsShortYearErr := time.Parse("MM-dd-YY", y)
-			_, dtUsLongYearErr := time.Parse("MM-dd-YYYY", y)
-			_, dtISOErr := time.Parse("YYYY-MM-DD", y)
-			_, dtParseErr := dateparse.ParseAny(y)
-
-			fmt.Println("date parse error : ", dtParseErr)
-			if fErr == nil {
-				buffer.WriteString(" double")
-			} else if bErr == nil {
-				buffer.WriteString(" bool")
-			} else if iErr == nil {
-				buffer.WriteString(" int")
-			} else if dtUsShortYearErr == nil {
-				buffer.WriteString(" date")
-			} else if dtUsLongYearErr == nil {
-				buffer.WriteString(" date")
-			} else if dtISOErr == nil {
-				buffer.WriteString(" date")
-			} else if dtParseErr == nil {
-				buffer.WriteString(" date")
-			} else {
-				buffer.WriteString(" varchar(0)")
+			col.HasValue = true
+			valueType := classifyValue(value)
+			col.Type = promote(col.Type, valueType)
+			if valueType == TypeDate && valueHasTime(value) {
+				col.HasTime = true
 			}
+			if len(value) > col.MaxLen {
+				col.MaxLen = len(value)
+			}
+		}
+	}
+
+	for _, col := range columns {
+		if !col.HasValue {
+			// Never saw a value to type off of; fall back to the widest type.
+			col.Type = TypeVarchar
 		}
-		break
 	}
 
-	buffer.WriteString(`);`)
+	fileNm := filepath.Base(*path)
+	tableName := strings.Replace(fileNm, ".csv", "", -1)
+
 	newFileName := filepath.Base(*path)
 	newFileName = newFileName[0:len(newFileName)-len(filepath.Ext(newFileName))] + "out" + ".sql"
 	r := filepath.Dir(*path)
-	return buffer.String(), filepath.Join(r, newFileName)
+	return tableName, columns, filepath.Join(r, newFileName), nil
+}
+
+// classifyValue returns the narrowest type a single value fits in.
+func classifyValue(s string) InferredType {
+	if _, err := strconv.ParseBool(s); err == nil {
+		return TypeBool
+	}
+	// Base 10: base 0 would accept "0x1F"/"0b101"/"1_000" as ints (and
+	// silently reinterpret leading-zero tokens like a "01234" ZIP code as
+	// octal), none of which are valid bare numeric literals in the SQL
+	// we emit.
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return TypeInt
+	}
+	// ParseFloat accepts underscore digit separators ("1_000") even without a
+	// base prefix; those aren't valid SQL numeric literals either.
+	if _, err := strconv.ParseFloat(s, 64); err == nil && !strings.Contains(s, "_") {
+		return TypeDouble
+	}
+	if _, err := dateparse.ParseAny(s); err == nil {
+		return TypeDate
+	}
+	return TypeVarchar
+}
+
+// valueHasTime reports whether a value classified as TypeDate also carries a
+// non-midnight time component, so dialects can pick DATE vs DATETIME/TIMESTAMP.
+func valueHasTime(s string) bool {
+	t, err := dateparse.ParseAny(s)
+	if err != nil {
+		return false
+	}
+	return t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0
+}
+
+// promote widens a column's running type to accommodate a newly observed value.
+func promote(current, observed InferredType) InferredType {
+	if observed > current {
+		return observed
+	}
+	return current
+}
+
+// parseNullTokens turns a comma-separated -null-tokens value into a lookup
+// set. A token wrapped in double quotes (e.g. `""`) is unquoted, so users can
+// spell out the empty string explicitly.
+func parseNullTokens(raw string) map[string]bool {
+	tokens := strings.Split(raw, ",")
+	set := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		if len(t) >= 2 && strings.HasPrefix(t, `"`) && strings.HasSuffix(t, `"`) {
+			t = t[1 : len(t)-1]
+		}
+		set[t] = true
+	}
+	return set
+}
+
+// varcharSize rounds n up to the next power of two, with a floor of 32; it's
+// shared by every Dialect's string-type mapping.
+func varcharSize(n int) int {
+	size := 32
+	for size < n {
+		size *= 2
+	}
+	return size
 }
 
 func checkError(message string, err error) {
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestClassifyValueRejectsNonDecimalIntLiterals(t *testing.T) {
+	cases := []struct {
+		value string
+		want  InferredType
+	}{
+		{"42", TypeInt},
+		{"0x1F", TypeVarchar},
+		{"0b101", TypeVarchar},
+		{"1_000", TypeVarchar},
+		{"01234", TypeInt}, // a leading-zero token like a ZIP code is still a valid base-10 int
+	}
+	for _, c := range cases {
+		if got := classifyValue(c.value); got != c.want {
+			t.Errorf("classifyValue(%q) = %v, want %v", c.value, got, c.want)
+		}
+	}
+}
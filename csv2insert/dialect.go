@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dialect maps inferred columns to the DDL a specific database expects,
+// isolating the per-database quirks (type names, identifier quoting) that
+// used to be hard-coded into ReadCSV2.
+type Dialect interface {
+	// MapType renders col's inferred type as this dialect's type name.
+	MapType(col *Column) string
+	// QuoteIdent quotes a table or column name for this dialect.
+	QuoteIdent(name string) string
+	// CreateTable renders the full CREATE TABLE statement for tableName.
+	CreateTable(tableName string, columns []*Column) string
+	// BoolLiteral renders a boolean value as this dialect's literal, so
+	// formatSQLValue never has to pass a raw CSV token (e.g. "t", "1")
+	// through as if it were valid SQL.
+	BoolLiteral(v bool) string
+}
+
+// dialects holds every registered Dialect, keyed by its -dialect flag name.
+var dialects = map[string]Dialect{
+	"thoughtspot": ThoughtSpotDialect{},
+	"postgres":    PostgresDialect{},
+	"mysql":       MySQLDialect{},
+	"snowflake":   SnowflakeDialect{},
+	"bigquery":    BigQueryDialect{},
+}
+
+// dialectFor resolves a -dialect flag value to its Dialect implementation.
+func dialectFor(name string) (Dialect, error) {
+	d, ok := dialects[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported -dialect %q", name)
+	}
+	return d, nil
+}
+
+// quoteTableBuilder renders a standard `CREATE TABLE ident (...)` statement
+// using d for type mapping and identifier quoting; every dialect here shares
+// this layout and only differs in MapType/QuoteIdent.
+func quoteTableBuilder(d Dialect, tableName string, columns []*Column) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("CREATE TABLE ")
+	buffer.WriteString(d.QuoteIdent(tableName))
+	buffer.WriteString(" (\n")
+	for i, col := range columns {
+		buffer.WriteString("  ")
+		buffer.WriteString(d.QuoteIdent(col.Name))
+		buffer.WriteString(" ")
+		buffer.WriteString(d.MapType(col))
+		if !col.Nullable {
+			buffer.WriteString(" NOT NULL")
+		}
+		if i < len(columns)-1 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString(");\n")
+	return buffer.String()
+}
+
+// ThoughtSpotDialect targets ThoughtSpot Falcon's TQL, the tool's original
+// (and default) output.
+type ThoughtSpotDialect struct{}
+
+func (ThoughtSpotDialect) QuoteIdent(name string) string { return `"` + name + `"` }
+
+func (ThoughtSpotDialect) MapType(col *Column) string {
+	switch col.Type {
+	case TypeBool:
+		return "bool"
+	case TypeInt:
+		return "int"
+	case TypeDouble:
+		return "double"
+	case TypeDate:
+		return "date"
+	default:
+		return fmt.Sprintf("varchar(%d)", varcharSize(col.MaxLen))
+	}
+}
+
+func (d ThoughtSpotDialect) CreateTable(tableName string, columns []*Column) string {
+	return quoteTableBuilder(d, tableName, columns)
+}
+
+func (ThoughtSpotDialect) BoolLiteral(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// PostgresDialect targets PostgreSQL.
+type PostgresDialect struct{}
+
+func (PostgresDialect) QuoteIdent(name string) string { return `"` + name + `"` }
+
+func (PostgresDialect) MapType(col *Column) string {
+	switch col.Type {
+	case TypeBool:
+		return "boolean"
+	case TypeInt:
+		return "bigint"
+	case TypeDouble:
+		return "double precision"
+	case TypeDate:
+		if col.HasTime {
+			return "timestamp"
+		}
+		return "date"
+	default:
+		return fmt.Sprintf("varchar(%d)", varcharSize(col.MaxLen))
+	}
+}
+
+func (d PostgresDialect) CreateTable(tableName string, columns []*Column) string {
+	return quoteTableBuilder(d, tableName, columns)
+}
+
+func (PostgresDialect) BoolLiteral(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// MySQLDialect targets MySQL, which quotes identifiers with backticks and
+// distinguishes DATE from DATETIME.
+type MySQLDialect struct{}
+
+func (MySQLDialect) QuoteIdent(name string) string { return "`" + name + "`" }
+
+func (MySQLDialect) MapType(col *Column) string {
+	switch col.Type {
+	case TypeBool:
+		return "tinyint(1)"
+	case TypeInt:
+		return "bigint"
+	case TypeDouble:
+		return "double"
+	case TypeDate:
+		if col.HasTime {
+			return "datetime"
+		}
+		return "date"
+	default:
+		return fmt.Sprintf("varchar(%d)", varcharSize(col.MaxLen))
+	}
+}
+
+func (d MySQLDialect) CreateTable(tableName string, columns []*Column) string {
+	return quoteTableBuilder(d, tableName, columns)
+}
+
+func (MySQLDialect) BoolLiteral(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// SnowflakeDialect targets Snowflake.
+type SnowflakeDialect struct{}
+
+func (SnowflakeDialect) QuoteIdent(name string) string { return `"` + name + `"` }
+
+func (SnowflakeDialect) MapType(col *Column) string {
+	switch col.Type {
+	case TypeBool:
+		return "boolean"
+	case TypeInt:
+		return "number(38,0)"
+	case TypeDouble:
+		return "float"
+	case TypeDate:
+		if col.HasTime {
+			return "timestamp_ntz"
+		}
+		return "date"
+	default:
+		return fmt.Sprintf("varchar(%d)", varcharSize(col.MaxLen))
+	}
+}
+
+func (d SnowflakeDialect) CreateTable(tableName string, columns []*Column) string {
+	return quoteTableBuilder(d, tableName, columns)
+}
+
+func (SnowflakeDialect) BoolLiteral(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// BigQueryDialect targets BigQuery, which spells its types differently and
+// only needs backtick-quoting for identifiers that require it. QuoteIdent
+// leaves plain identifiers (letters, digits and underscores, not starting
+// with a digit) bare and backtick-quotes anything else, such as a .csv-derived
+// table name with a hyphen. Reserved words are not detected and stay bare.
+type BigQueryDialect struct{}
+
+var bigQueryPlainIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (BigQueryDialect) QuoteIdent(name string) string {
+	if bigQueryPlainIdent.MatchString(name) {
+		return name
+	}
+	return "`" + name + "`"
+}
+
+func (BigQueryDialect) MapType(col *Column) string {
+	switch col.Type {
+	case TypeBool:
+		return "BOOL"
+	case TypeInt:
+		return "INT64"
+	case TypeDouble:
+		return "FLOAT64"
+	case TypeDate:
+		if col.HasTime {
+			return "TIMESTAMP"
+		}
+		return "DATE"
+	default:
+		return "STRING"
+	}
+}
+
+func (d BigQueryDialect) CreateTable(tableName string, columns []*Column) string {
+	return quoteTableBuilder(d, tableName, columns)
+}
+
+func (BigQueryDialect) BoolLiteral(v bool) string {
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
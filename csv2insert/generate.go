@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/araddon/dateparse"
+)
+
+// generateOutput builds the full contents of outPath for the requested
+// -format, plus any companion files that format needs (only -format tsload
+// produces one: a shell script alongside the schema file).
+func generateOutput(path string, opts Options, dialect Dialect, tableName string, columns []*Column, ddl string, outPath string) (string, map[string]string, error) {
+	switch opts.Format {
+	case "", "tql":
+		return ddl, nil, nil
+
+	case "insert":
+		inserts, err := buildInserts(path, opts, dialect, tableName, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		return ddl + "\n" + inserts, nil, nil
+
+	case "copy":
+		block, err := buildCopyBlock(path, opts, dialect, tableName, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		return ddl + "\n" + block, nil, nil
+
+	case "tsload":
+		script := buildTsloadScript(path, opts, tableName, outPath)
+		scriptPath := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + ".sh"
+		return ddl, map[string]string{scriptPath: script}, nil
+
+	default:
+		return "", nil, fmt.Errorf("unsupported -format %q", opts.Format)
+	}
+}
+
+// buildInserts re-reads path and renders its rows as batched INSERT
+// statements, respecting opts.Batch rows per statement.
+func buildInserts(path string, opts Options, dialect Dialect, tableName string, columns []*Column) (string, error) {
+	_, next, closer, err := openDataRows(path, opts)
+	if err != nil {
+		return "", err
+	}
+	defer closer.Close()
+
+	nullTokens := parseNullTokens(opts.NullTokens)
+	colNames := quotedColumnNames(dialect, columns)
+
+	batchSize := opts.Batch
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	var buf bytes.Buffer
+	rowsInBatch := 0
+	for {
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if rowsInBatch == 0 {
+			buf.WriteString("INSERT INTO ")
+			buf.WriteString(dialect.QuoteIdent(tableName))
+			buf.WriteString(" (")
+			buf.WriteString(strings.Join(colNames, ", "))
+			buf.WriteString(") VALUES\n")
+		} else {
+			buf.WriteString(",\n")
+		}
+
+		values := make([]string, len(columns))
+		for j, col := range columns {
+			v := ""
+			if j < len(record) {
+				v = record[j]
+			}
+			values[j] = formatSQLValue(v, col, dialect, nullTokens)
+		}
+		buf.WriteString("  (")
+		buf.WriteString(strings.Join(values, ", "))
+		buf.WriteString(")")
+
+		rowsInBatch++
+		if rowsInBatch >= batchSize {
+			buf.WriteString(";\n")
+			rowsInBatch = 0
+		}
+	}
+	if rowsInBatch > 0 {
+		buf.WriteString(";\n")
+	}
+	return buf.String(), nil
+}
+
+// buildCopyBlock re-reads path and renders a Postgres `COPY ... FROM STDIN`
+// block in COPY's tab-separated text format, terminated by "\.".
+func buildCopyBlock(path string, opts Options, dialect Dialect, tableName string, columns []*Column) (string, error) {
+	_, next, closer, err := openDataRows(path, opts)
+	if err != nil {
+		return "", err
+	}
+	defer closer.Close()
+
+	nullTokens := parseNullTokens(opts.NullTokens)
+	colNames := quotedColumnNames(dialect, columns)
+
+	var buf bytes.Buffer
+	buf.WriteString("COPY ")
+	buf.WriteString(dialect.QuoteIdent(tableName))
+	buf.WriteString(" (")
+	buf.WriteString(strings.Join(colNames, ", "))
+	buf.WriteString(") FROM STDIN;\n")
+
+	for {
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		fields := make([]string, len(columns))
+		for j := range columns {
+			v := ""
+			if j < len(record) {
+				v = record[j]
+			}
+			if nullTokens[v] {
+				fields[j] = `\N`
+			} else {
+				fields[j] = copyEscape(v)
+			}
+		}
+		buf.WriteString(strings.Join(fields, "\t"))
+		buf.WriteString("\n")
+	}
+	buf.WriteString(`\.` + "\n")
+	return buf.String(), nil
+}
+
+// buildTsloadScript renders a shell script that creates the schema and then
+// bulk-loads the original CSV with ThoughtSpot's tsload.
+func buildTsloadScript(path string, opts Options, tableName string, schemaPath string) string {
+	var buf bytes.Buffer
+	buf.WriteString("#!/bin/bash\n")
+	buf.WriteString("set -euo pipefail\n\n")
+	buf.WriteString(fmt.Sprintf("tql < %q\n", schemaPath))
+	buf.WriteString(fmt.Sprintf("tsload --source_file %q --target_table %q --format csv --field_separator %q",
+		path, tableName, string(opts.Delim)))
+	if !opts.NoHeader {
+		buf.WriteString(" --has_header_row")
+	}
+	buf.WriteString(" --empty_target\n")
+	return buf.String()
+}
+
+// formatSQLValue renders a single CSV field as a SQL literal for col's type,
+// emitting NULL for null tokens and escaping/quoting strings and dates. With
+// -sample set, col.Type was inferred from only a prefix of the file, so
+// every value is re-validated against it here; a value that doesn't actually
+// fit falls back to a quoted string literal instead of being echoed as a
+// bare (and possibly invalid) token.
+func formatSQLValue(value string, col *Column, dialect Dialect, nullTokens map[string]bool) string {
+	if nullTokens[value] {
+		return "NULL"
+	}
+	switch col.Type {
+	case TypeBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return dialect.BoolLiteral(b)
+		}
+		return "'" + sqlEscape(value) + "'"
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value
+		}
+		return "'" + sqlEscape(value) + "'"
+	case TypeDouble:
+		if _, err := strconv.ParseFloat(value, 64); err == nil && !strings.Contains(value, "_") {
+			return value
+		}
+		return "'" + sqlEscape(value) + "'"
+	case TypeDate:
+		if t, err := dateparse.ParseAny(value); err == nil {
+			if col.HasTime {
+				return "'" + t.Format("2006-01-02 15:04:05") + "'"
+			}
+			return "'" + t.Format("2006-01-02") + "'"
+		}
+		return "'" + sqlEscape(value) + "'"
+	default:
+		return "'" + sqlEscape(value) + "'"
+	}
+}
+
+// sqlEscape escapes a string literal for SQL by doubling single quotes.
+func sqlEscape(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// copyEscape escapes a field for Postgres COPY's tab-separated text format.
+func copyEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+	return r.Replace(s)
+}
+
+func quotedColumnNames(dialect Dialect, columns []*Column) []string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = dialect.QuoteIdent(col.Name)
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatSQLValue(t *testing.T) {
+	dialect := PostgresDialect{}
+	nullTokens := map[string]bool{"": true, "NULL": true}
+
+	cases := []struct {
+		name  string
+		value string
+		col   *Column
+		want  string
+	}{
+		{"bool true", "true", &Column{Type: TypeBool}, "TRUE"},
+		{"bool invalid falls back to string", "maybe", &Column{Type: TypeBool}, "'maybe'"},
+		{"int valid", "42", &Column{Type: TypeInt}, "42"},
+		{"int mismatch falls back to string", "3.14", &Column{Type: TypeInt}, "'3.14'"},
+		{"double valid", "3.14", &Column{Type: TypeDouble}, "3.14"},
+		{"date without time", "2020-01-02", &Column{Type: TypeDate}, "'2020-01-02'"},
+		{"date with time", "2020-01-02T15:04:05Z", &Column{Type: TypeDate, HasTime: true}, "'2020-01-02 15:04:05'"},
+		{"null token", "", &Column{Type: TypeVarchar}, "NULL"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := formatSQLValue(c.value, c.col, dialect, nullTokens)
+			if got != c.want {
+				t.Fatalf("formatSQLValue(%q) = %q, want %q", c.value, got, c.want)
+			}
+		})
+	}
+}
+
+// TestBuildInsertsRevalidatesOutOfSampleValues guards against -sample
+// bounding type inference to a prefix of the file: a later row that
+// doesn't actually fit the inferred type must fall back to a quoted
+// string literal instead of being echoed as a bare, invalid token.
+func TestBuildInsertsRevalidatesOutOfSampleValues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nums.csv")
+	if err := ioutil.WriteFile(path, []byte("col\n1\n2\noops\n"), 0644); err != nil {
+		t.Fatalf("write temp csv: %v", err)
+	}
+
+	opts := Options{
+		SampleRows: 2,
+		NullTokens: `"",NULL,N/A,\N`,
+		Delim:      ',',
+		Quote:      '"',
+		Encoding:   "utf-8",
+		Batch:      1000,
+	}
+
+	_, columns, _, err := ReadCSV2(&path, opts)
+	if err != nil {
+		t.Fatalf("ReadCSV2: %v", err)
+	}
+	if columns[0].Type != TypeInt {
+		t.Fatalf("expected sampling to infer TypeInt, got %v", columns[0].Type)
+	}
+
+	inserts, err := buildInserts(path, opts, PostgresDialect{}, "nums", columns)
+	if err != nil {
+		t.Fatalf("buildInserts: %v", err)
+	}
+
+	if !strings.Contains(inserts, "(1)") || !strings.Contains(inserts, "(2)") {
+		t.Fatalf("expected in-sample ints emitted bare, got: %s", inserts)
+	}
+	if !strings.Contains(inserts, "('oops')") {
+		t.Fatalf("expected out-of-sample value quoted as a string literal, got: %s", inserts)
+	}
+}
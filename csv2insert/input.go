@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// openCSVReader opens path and builds a csv.Reader configured per opts:
+// the delimiter, an optional non-UTF-8 encoding, a preamble skip, and (via
+// quoteRewriter) a non-default quote character.
+func openCSVReader(path string, opts Options) (*csv.Reader, *os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the file is not found || wrong root")
+	}
+
+	enc, err := decoderFor(opts.Encoding)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	var r io.Reader = transform.NewReader(f, enc.NewDecoder())
+
+	if opts.Quote != '"' {
+		if opts.Quote > 127 {
+			f.Close()
+			return nil, nil, fmt.Errorf("invalid -quote %q: only single-byte quote characters are supported", opts.Quote)
+		}
+		r = newQuoteRewriter(r, byte(opts.Quote), '"', opts.Delim)
+	}
+
+	br := bufio.NewReader(r)
+	for i := 0; i < opts.SkipLines; i++ {
+		if _, err := br.ReadString('\n'); err != nil && err != io.EOF {
+			f.Close()
+			return nil, nil, err
+		}
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = opts.Delim
+	return reader, f, nil
+}
+
+// openDataRows opens path per opts and returns a function yielding each data
+// row in turn (io.EOF once exhausted), having already consumed and resolved
+// the header row (sanitized, or synthesized under opts.NoHeader).
+func openDataRows(path string, opts Options) ([]string, func() ([]string, error), io.Closer, error) {
+	reader, f, err := openCSVReader(path, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	firstRow, err := reader.Read()
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, err
+	}
+
+	var header []string
+	var pending []string
+	if opts.NoHeader {
+		header = syntheticHeaders(len(firstRow))
+		pending = firstRow
+	} else {
+		header = sanitizeHeaders(firstRow)
+	}
+
+	next := func() ([]string, error) {
+		if pending != nil {
+			row := pending
+			pending = nil
+			return row, nil
+		}
+		return reader.Read()
+	}
+
+	return header, next, f, nil
+}
+
+// decoderFor maps an -encoding flag value to its golang.org/x/text encoding.
+func decoderFor(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(name) {
+	case "", "utf-8", "utf8":
+		return unicode.UTF8BOM, nil
+	case "gbk":
+		return simplifiedchinese.GBK, nil
+	case "latin1", "iso-8859-1", "iso8859-1":
+		return charmap.ISO8859_1, nil
+	case "utf16le", "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	default:
+		return nil, fmt.Errorf("unsupported -encoding %q", name)
+	}
+}
+
+// quoteRewriterState tracks where quoteRewriter is within a CSV field, so it
+// only rewrites a custom quote byte when it's actually acting as a field
+// delimiter rather than literal field data (e.g. an apostrophe in free text
+// when -quote "'" is set).
+type quoteRewriterState int
+
+const (
+	fieldStart quoteRewriterState = iota
+	inUnquotedField
+	inQuotedField
+)
+
+// quoteRewriter streams bytes from r, rewriting a single-byte custom quote
+// character to the plain ASCII double quote that encoding/csv understands.
+// Unlike a blind byte substitution, it tracks field boundaries (via delim
+// and newline bytes) so it only rewrites the custom quote where it's
+// opening, closing, or escaping a quoted field, leaving occurrences of that
+// byte inside unquoted field data untouched.
+type quoteRewriter struct {
+	br      *bufio.Reader
+	from    byte
+	to      byte
+	delim   byte // 0 if opts.Delim doesn't fit in a single byte; field boundaries then rely on newlines only
+	state   quoteRewriterState
+	pending byte // a second output byte held over to the next Read when p ran out of room mid-escape
+	hasPend bool
+}
+
+// newQuoteRewriter wraps r so occurrences of from are rewritten to to only
+// where they delimit a CSV field, per quoteRewriter's state machine.
+func newQuoteRewriter(r io.Reader, from, to byte, delim rune) *quoteRewriter {
+	var delimByte byte
+	if delim > 0 && delim < 128 {
+		delimByte = byte(delim)
+	}
+	return &quoteRewriter{br: bufio.NewReader(r), from: from, to: to, delim: delimByte}
+}
+
+func (q *quoteRewriter) isFieldBoundary(b byte) bool {
+	return b == '\n' || b == '\r' || (q.delim != 0 && b == q.delim)
+}
+
+func (q *quoteRewriter) Read(p []byte) (int, error) {
+	n := 0
+	if q.hasPend && n < len(p) {
+		p[n] = q.pending
+		n++
+		q.hasPend = false
+	}
+	for n < len(p) {
+		b, err := q.br.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		switch q.state {
+		case fieldStart:
+			if b == q.from {
+				p[n] = q.to
+				n++
+				q.state = inQuotedField
+				continue
+			}
+			p[n] = b
+			n++
+			if !q.isFieldBoundary(b) {
+				q.state = inUnquotedField
+			}
+
+		case inUnquotedField:
+			p[n] = b
+			n++
+			if q.isFieldBoundary(b) {
+				q.state = fieldStart
+			}
+
+		case inQuotedField:
+			if b == q.from {
+				// b is our custom quote byte: peek ahead to tell a doubled
+				// quote (an escaped literal quote inside the field) from
+				// the closing quote.
+				if next, err := q.br.Peek(1); err == nil && len(next) == 1 && next[0] == q.from {
+					q.br.ReadByte() // consume the peeked byte too
+					// Inside the now double-quoted field this needs no
+					// escaping of its own: emit the literal quote byte.
+					p[n] = q.from
+					n++
+					continue
+				}
+				p[n] = q.to
+				n++
+				q.state = inUnquotedField
+				continue
+			}
+			if b == q.to {
+				// A literal '"' already in the source data would close our
+				// rewritten field early unless doubled.
+				p[n] = q.to
+				n++
+				if n < len(p) {
+					p[n] = q.to
+					n++
+				} else {
+					q.pending = q.to
+					q.hasPend = true
+				}
+				continue
+			}
+			p[n] = b
+			n++
+		}
+	}
+	return n, nil
+}
+
+// parseDelim resolves a -delim flag value to a single rune, accepting the
+// literal character or one of the common names used by exchange dumps.
+func parseDelim(s string) (rune, error) {
+	switch strings.ToLower(s) {
+	case "tab", `\t`:
+		return '\t', nil
+	case "semicolon":
+		return ';', nil
+	case "pipe":
+		return '|', nil
+	}
+	r := []rune(s)
+	if len(r) != 1 {
+		return 0, fmt.Errorf("invalid -delim %q: expected a single character or one of tab/semicolon/pipe", s)
+	}
+	return r[0], nil
+}
+
+// parseQuote resolves a -quote flag value to a single rune.
+func parseQuote(s string) (rune, error) {
+	r := []rune(s)
+	if len(r) != 1 {
+		return 0, fmt.Errorf("invalid -quote %q: expected a single character", s)
+	}
+	return r[0], nil
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// sanitizeHeaders lowercases header names, replaces non-alphanumeric runs
+// with underscores, and numbers any resulting collisions so every name is a
+// valid, unique SQL identifier.
+func sanitizeHeaders(headers []string) []string {
+	seen := make(map[string]int, len(headers))
+	out := make([]string, len(headers))
+	for i, h := range headers {
+		name := nonAlnum.ReplaceAllString(strings.ToLower(strings.TrimSpace(h)), "_")
+		name = strings.Trim(name, "_")
+		if name == "" {
+			name = "col"
+		}
+		if seen[name] > 0 {
+			base := name
+			for n := seen[base] + 1; ; n++ {
+				candidate := fmt.Sprintf("%s_%d", base, n)
+				if seen[candidate] == 0 {
+					seen[base] = n
+					name = candidate
+					break
+				}
+			}
+		}
+		seen[name]++
+		out[i] = name
+	}
+	return out
+}
+
+// syntheticHeaders generates col_1..col_N for -no-header input.
+func syntheticHeaders(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = fmt.Sprintf("col_%d", i+1)
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+// TestQuoteRewriterEscaping pins down the two escaping rules a CSV-aware
+// quote rewrite must get right: a doubled custom quote is an escaped
+// literal quote character (no re-escaping needed once rewritten), while a
+// bare '"' already present in the source data must be doubled so the
+// rewritten stream stays valid CSV for encoding/csv to parse.
+func TestQuoteRewriterEscaping(t *testing.T) {
+	raw := `'it''s','has"quote'` + "\n"
+	rw := newQuoteRewriter(strings.NewReader(raw), '\'', '"', ',')
+	record, err := csv.NewReader(bufio.NewReader(rw)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error reading rewritten CSV: %v", err)
+	}
+	want := []string{"it's", `has"quote`}
+	if len(record) != len(want) || record[0] != want[0] || record[1] != want[1] {
+		t.Fatalf("got %q, want %q", record, want)
+	}
+}
+
+// TestQuoteRewriterLeavesUnquotedDataAlone guards against the original blind
+// byte-substitution bug: a custom quote byte that appears as literal
+// unquoted field data (e.g. an apostrophe in a name) must pass through
+// unchanged, not get promoted into a CSV quote character.
+func TestQuoteRewriterLeavesUnquotedDataAlone(t *testing.T) {
+	raw := "O'Brien,Smith\n"
+	rw := newQuoteRewriter(strings.NewReader(raw), '\'', '"', ',')
+	record, err := csv.NewReader(bufio.NewReader(rw)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"O'Brien", "Smith"}
+	if len(record) != len(want) || record[0] != want[0] || record[1] != want[1] {
+		t.Fatalf("got %q, want %q", record, want)
+	}
+}